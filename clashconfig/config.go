@@ -0,0 +1,137 @@
+// Package clashconfig holds the typed schema for mihomo/Clash
+// configuration files and a field-aware merge between two configs.
+package clashconfig
+
+// Config is a mihomo/Clash configuration file.
+type Config struct {
+	Port               int                     `yaml:"port"`
+	SocksPort          int                     `yaml:"socks-port"`
+	RedirPort          int                     `yaml:"redir-port"`
+	MixedPort          int                     `yaml:"mixed-port"`
+	AllowLan           bool                    `yaml:"allow-lan"`
+	BindAddress        string                  `yaml:"bind-address"`
+	Mode               string                  `yaml:"mode"`
+	LogLevel           string                  `yaml:"log-level"`
+	ExternalController string                  `yaml:"external-controller"`
+	DNS                *DNS                    `yaml:"dns,omitempty"`
+	Tun                *Tun                    `yaml:"tun,omitempty"`
+	Sniffer            *Sniffer                `yaml:"sniffer,omitempty"`
+	Hosts              map[string]string       `yaml:"hosts,omitempty"`
+	Profile            *Profile                `yaml:"profile,omitempty"`
+	Proxies            []Proxy                 `yaml:"proxies"`
+	ProxyGroups        []ProxyGroup            `yaml:"proxy-groups"`
+	Rules              []string                `yaml:"rules"`
+	RuleProviders      map[string]RuleProvider `yaml:"rule-providers,omitempty"`
+}
+
+// DNS is the top-level `dns` block.
+type DNS struct {
+	Enable           bool              `yaml:"enable"`
+	Listen           string            `yaml:"listen,omitempty"`
+	EnhancedMode     string            `yaml:"enhanced-mode,omitempty"`
+	FakeIPRange      string            `yaml:"fake-ip-range,omitempty"`
+	Nameserver       []string          `yaml:"nameserver,omitempty"`
+	Fallback         []string          `yaml:"fallback,omitempty"`
+	FallbackFilter   *FallbackFilter   `yaml:"fallback-filter,omitempty"`
+	NameserverPolicy map[string]string `yaml:"nameserver-policy,omitempty"`
+}
+
+// FallbackFilter is `dns.fallback-filter`.
+type FallbackFilter struct {
+	GeoIP     bool     `yaml:"geoip,omitempty"`
+	GeoIPCode string   `yaml:"geoip-code,omitempty"`
+	IPCIDR    []string `yaml:"ipcidr,omitempty"`
+	Domain    []string `yaml:"domain,omitempty"`
+}
+
+// Tun is the top-level `tun` block.
+type Tun struct {
+	Enable              bool     `yaml:"enable"`
+	Stack               string   `yaml:"stack,omitempty"`
+	DNSHijack           []string `yaml:"dns-hijack,omitempty"`
+	AutoRoute           bool     `yaml:"auto-route,omitempty"`
+	AutoDetectInterface bool     `yaml:"auto-detect-interface,omitempty"`
+}
+
+// Sniffer is the top-level `sniffer` block.
+type Sniffer struct {
+	Enable      bool     `yaml:"enable"`
+	Sniff       []string `yaml:"sniff,omitempty"`
+	ForceDomain []string `yaml:"force-domain,omitempty"`
+	SkipDomain  []string `yaml:"skip-domain,omitempty"`
+}
+
+// Profile is the top-level `profile` block.
+type Profile struct {
+	StoreSelected bool `yaml:"store-selected,omitempty"`
+	StoreFakeIP   bool `yaml:"store-fake-ip,omitempty"`
+}
+
+// Proxy is a single entry in `proxies`.
+type Proxy struct {
+	Name           string         `yaml:"name"`
+	Type           string         `yaml:"type"`
+	Server         string         `yaml:"server"`
+	Port           int            `yaml:"port"`
+	Password       string         `yaml:"password,omitempty"`
+	UUID           string         `yaml:"uuid,omitempty"`
+	Cipher         string         `yaml:"cipher,omitempty"`
+	Network        string         `yaml:"network,omitempty"`
+	UDP            bool           `yaml:"udp,omitempty"`
+	TLS            bool           `yaml:"tls,omitempty"`
+	SkipCertVerify bool           `yaml:"skip-cert-verify,omitempty"`
+	SNI            string         `yaml:"sni,omitempty"`
+	ServerName     string         `yaml:"servername,omitempty"`
+	ALPN           []string       `yaml:"alpn,omitempty"`
+	Flow           string         `yaml:"flow,omitempty"`
+	AlterID        int            `yaml:"alterId,omitempty"`
+	Plugin         string         `yaml:"plugin,omitempty"`
+	PluginOpts     map[string]any `yaml:"plugin-opts,omitempty"`
+	WSOpts         *WSOpts        `yaml:"ws-opts,omitempty"`
+	GrpcOpts       *GrpcOpts      `yaml:"grpc-opts,omitempty"`
+	RealityOpts    *RealityOpts   `yaml:"reality-opts,omitempty"`
+	H2Opts         *H2Opts        `yaml:"h2-opts,omitempty"`
+	Extra          map[string]any `yaml:",inline,omitempty"`
+}
+
+// WSOpts is a proxy's `ws-opts` transport block.
+type WSOpts struct {
+	Path    string            `yaml:"path,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+}
+
+// GrpcOpts is a proxy's `grpc-opts` transport block.
+type GrpcOpts struct {
+	GrpcServiceName string `yaml:"grpc-service-name,omitempty"`
+}
+
+// RealityOpts is a proxy's `reality-opts` transport block.
+type RealityOpts struct {
+	PublicKey string `yaml:"public-key,omitempty"`
+	ShortID   string `yaml:"short-id,omitempty"`
+}
+
+// H2Opts is a proxy's `h2-opts` transport block.
+type H2Opts struct {
+	Host []string `yaml:"host,omitempty"`
+	Path string   `yaml:"path,omitempty"`
+}
+
+// ProxyGroup is a single entry in `proxy-groups`.
+type ProxyGroup struct {
+	Name     string   `yaml:"name"`
+	Type     string   `yaml:"type"`
+	Proxies  []string `yaml:"proxies"`
+	URL      string   `yaml:"url,omitempty"`
+	Interval int      `yaml:"interval,omitempty"`
+}
+
+// RuleProvider is a single entry in `rule-providers`.
+type RuleProvider struct {
+	Type     string `yaml:"type"`
+	Behavior string `yaml:"behavior"`
+	Format   string `yaml:"format,omitempty"`
+	URL      string `yaml:"url"`
+	Path     string `yaml:"path"`
+	Interval int    `yaml:"interval"`
+}
@@ -0,0 +1,247 @@
+package clashconfig
+
+// Merge deep-merges override into base: scalar fields follow "non-zero in
+// override wins", maps are merged key-by-key, the top-level Proxies,
+// ProxyGroups and Rules slices are concatenated with dedup (by name for
+// Proxies/ProxyGroups, by value for Rules), and sub-structs (DNS, Tun,
+// Sniffer, Profile) are recursed into rather than replaced wholesale.
+// Inside those sub-structs, a non-empty slice in override replaces base's
+// slice, and dns.fallback-filter is replaced as a whole. Either argument
+// may be nil.
+//
+// Merge works on the typed Config struct, so round-tripping through it
+// reorders keys and drops YAML comments rather than preserving them via
+// yaml.Node; this is acceptable for the generated output but means the
+// result should not be treated as a comment/order-preserving edit of base.
+// "Non-zero wins" also means a bool field can only be turned on by an
+// override, never back off: if base already has a field defaulting to
+// true (e.g. allow-lan, dns.enable), no override can force it to false.
+func Merge(base, override *Config) *Config {
+	if base == nil {
+		base = &Config{}
+	}
+	if override == nil {
+		return base
+	}
+
+	merged := *base
+
+	if override.Port != 0 {
+		merged.Port = override.Port
+	}
+	if override.SocksPort != 0 {
+		merged.SocksPort = override.SocksPort
+	}
+	if override.RedirPort != 0 {
+		merged.RedirPort = override.RedirPort
+	}
+	if override.MixedPort != 0 {
+		merged.MixedPort = override.MixedPort
+	}
+	if override.AllowLan {
+		merged.AllowLan = override.AllowLan
+	}
+	if override.BindAddress != "" {
+		merged.BindAddress = override.BindAddress
+	}
+	if override.Mode != "" {
+		merged.Mode = override.Mode
+	}
+	if override.LogLevel != "" {
+		merged.LogLevel = override.LogLevel
+	}
+	if override.ExternalController != "" {
+		merged.ExternalController = override.ExternalController
+	}
+
+	merged.DNS = mergeDNS(base.DNS, override.DNS)
+	merged.Tun = mergeTun(base.Tun, override.Tun)
+	merged.Sniffer = mergeSniffer(base.Sniffer, override.Sniffer)
+	merged.Profile = mergeProfile(base.Profile, override.Profile)
+	merged.Hosts = mergeStringMap(base.Hosts, override.Hosts)
+	merged.RuleProviders = mergeRuleProviders(base.RuleProviders, override.RuleProviders)
+
+	merged.Proxies = dedupProxiesByName(append(append([]Proxy{}, base.Proxies...), override.Proxies...))
+	merged.ProxyGroups = dedupGroupsByName(append(append([]ProxyGroup{}, base.ProxyGroups...), override.ProxyGroups...))
+	merged.Rules = dedupStrings(append(append([]string{}, base.Rules...), override.Rules...))
+
+	return &merged
+}
+
+func mergeDNS(base, override *DNS) *DNS {
+	if override == nil {
+		return base
+	}
+	if base == nil {
+		return override
+	}
+
+	merged := *base
+	if override.Enable {
+		merged.Enable = override.Enable
+	}
+	if override.Listen != "" {
+		merged.Listen = override.Listen
+	}
+	if override.EnhancedMode != "" {
+		merged.EnhancedMode = override.EnhancedMode
+	}
+	if override.FakeIPRange != "" {
+		merged.FakeIPRange = override.FakeIPRange
+	}
+	if len(override.Nameserver) > 0 {
+		merged.Nameserver = override.Nameserver
+	}
+	if len(override.Fallback) > 0 {
+		merged.Fallback = override.Fallback
+	}
+	if override.FallbackFilter != nil {
+		merged.FallbackFilter = override.FallbackFilter
+	}
+	merged.NameserverPolicy = mergeStringMap(base.NameserverPolicy, override.NameserverPolicy)
+	return &merged
+}
+
+func mergeTun(base, override *Tun) *Tun {
+	if override == nil {
+		return base
+	}
+	if base == nil {
+		return override
+	}
+
+	merged := *base
+	if override.Enable {
+		merged.Enable = override.Enable
+	}
+	if override.Stack != "" {
+		merged.Stack = override.Stack
+	}
+	if len(override.DNSHijack) > 0 {
+		merged.DNSHijack = override.DNSHijack
+	}
+	if override.AutoRoute {
+		merged.AutoRoute = override.AutoRoute
+	}
+	if override.AutoDetectInterface {
+		merged.AutoDetectInterface = override.AutoDetectInterface
+	}
+	return &merged
+}
+
+func mergeSniffer(base, override *Sniffer) *Sniffer {
+	if override == nil {
+		return base
+	}
+	if base == nil {
+		return override
+	}
+
+	merged := *base
+	if override.Enable {
+		merged.Enable = override.Enable
+	}
+	if len(override.Sniff) > 0 {
+		merged.Sniff = override.Sniff
+	}
+	if len(override.ForceDomain) > 0 {
+		merged.ForceDomain = override.ForceDomain
+	}
+	if len(override.SkipDomain) > 0 {
+		merged.SkipDomain = override.SkipDomain
+	}
+	return &merged
+}
+
+func mergeProfile(base, override *Profile) *Profile {
+	if override == nil {
+		return base
+	}
+	if base == nil {
+		return override
+	}
+
+	merged := *base
+	if override.StoreSelected {
+		merged.StoreSelected = override.StoreSelected
+	}
+	if override.StoreFakeIP {
+		merged.StoreFakeIP = override.StoreFakeIP
+	}
+	return &merged
+}
+
+func mergeStringMap(base, override map[string]string) map[string]string {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+func mergeRuleProviders(base, override map[string]RuleProvider) map[string]RuleProvider {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+	merged := make(map[string]RuleProvider, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// dedupProxiesByName collapses proxies sharing a name, keeping the first
+// occurrence's position but the last occurrence's data.
+func dedupProxiesByName(proxies []Proxy) []Proxy {
+	index := make(map[string]int, len(proxies))
+	result := make([]Proxy, 0, len(proxies))
+	for _, p := range proxies {
+		if i, ok := index[p.Name]; ok {
+			result[i] = p
+			continue
+		}
+		index[p.Name] = len(result)
+		result = append(result, p)
+	}
+	return result
+}
+
+// dedupGroupsByName collapses proxy-groups sharing a name, keeping the
+// first occurrence's position but the last occurrence's data.
+func dedupGroupsByName(groups []ProxyGroup) []ProxyGroup {
+	index := make(map[string]int, len(groups))
+	result := make([]ProxyGroup, 0, len(groups))
+	for _, g := range groups {
+		if i, ok := index[g.Name]; ok {
+			result[i] = g
+			continue
+		}
+		index[g.Name] = len(result)
+		result = append(result, g)
+	}
+	return result
+}
+
+// dedupStrings removes exact duplicate rule lines, keeping the first
+// occurrence.
+func dedupStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+package clashconfig
+
+import "testing"
+
+func TestMergeScalarFields(t *testing.T) {
+	base := &Config{Port: 7890, SocksPort: 7891, AllowLan: true, Mode: "rule"}
+	override := &Config{Port: 9999, Mode: "global"}
+
+	got := Merge(base, override)
+	if got.Port != 9999 {
+		t.Errorf("Port = %v, want 9999", got.Port)
+	}
+	if got.SocksPort != 7891 {
+		t.Errorf("SocksPort = %v, want 7891", got.SocksPort)
+	}
+	if got.Mode != "global" {
+		t.Errorf("Mode = %v, want global", got.Mode)
+	}
+	if !got.AllowLan {
+		t.Errorf("AllowLan = false, want true")
+	}
+}
+
+func TestMergeZeroFieldsDoNotOverride(t *testing.T) {
+	base := &Config{Port: 7890, SocksPort: 7891}
+	override := &Config{Port: 0, Mode: "rule"}
+
+	got := Merge(base, override)
+	if got.Port != 7890 {
+		t.Errorf("Port = %v, want 7890", got.Port)
+	}
+	if got.Mode != "rule" {
+		t.Errorf("Mode = %v, want rule", got.Mode)
+	}
+}
+
+func TestMergeRuleProvidersKeyByKey(t *testing.T) {
+	base := &Config{
+		RuleProviders: map[string]RuleProvider{
+			"direct": {Type: "http", URL: "https://example.com/direct"},
+		},
+	}
+	override := &Config{
+		RuleProviders: map[string]RuleProvider{
+			"reject": {Type: "http", URL: "https://example.com/reject"},
+		},
+	}
+
+	got := Merge(base, override)
+	if len(got.RuleProviders) != 2 {
+		t.Fatalf("len(RuleProviders) = %v, want 2", len(got.RuleProviders))
+	}
+	if got.RuleProviders["direct"].URL != "https://example.com/direct" {
+		t.Errorf("direct provider lost on merge")
+	}
+	if got.RuleProviders["reject"].URL != "https://example.com/reject" {
+		t.Errorf("reject provider missing after merge")
+	}
+}
+
+func TestMergeProxiesDedupByName(t *testing.T) {
+	base := &Config{Proxies: []Proxy{{Name: "A", Server: "old.example.com"}}}
+	override := &Config{Proxies: []Proxy{{Name: "A", Server: "new.example.com"}, {Name: "B"}}}
+
+	got := Merge(base, override)
+	if len(got.Proxies) != 2 {
+		t.Fatalf("len(Proxies) = %v, want 2", len(got.Proxies))
+	}
+	if got.Proxies[0].Server != "new.example.com" {
+		t.Errorf("Proxies[0].Server = %v, want new.example.com (override wins)", got.Proxies[0].Server)
+	}
+}
+
+func TestMergeRulesDedup(t *testing.T) {
+	base := &Config{Rules: []string{"MATCH,PROXY"}}
+	override := &Config{Rules: []string{"RULE-SET,direct,DIRECT", "MATCH,PROXY"}}
+
+	got := Merge(base, override)
+	if len(got.Rules) != 2 {
+		t.Fatalf("len(Rules) = %v, want 2: %v", len(got.Rules), got.Rules)
+	}
+}
+
+func TestMergeDNSRecurses(t *testing.T) {
+	base := &Config{DNS: &DNS{Enable: true, Listen: "0.0.0.0:53", Nameserver: []string{"1.1.1.1"}}}
+	override := &Config{DNS: &DNS{FakeIPRange: "198.18.0.1/16"}}
+
+	got := Merge(base, override)
+	if got.DNS.Listen != "0.0.0.0:53" {
+		t.Errorf("DNS.Listen = %v, want 0.0.0.0:53 (preserved from base)", got.DNS.Listen)
+	}
+	if got.DNS.FakeIPRange != "198.18.0.1/16" {
+		t.Errorf("DNS.FakeIPRange = %v, want 198.18.0.1/16 (from override)", got.DNS.FakeIPRange)
+	}
+	if len(got.DNS.Nameserver) != 1 || got.DNS.Nameserver[0] != "1.1.1.1" {
+		t.Errorf("DNS.Nameserver = %v, want [1.1.1.1]", got.DNS.Nameserver)
+	}
+}
+
+func TestMergeNilArguments(t *testing.T) {
+	if got := Merge(nil, nil); got == nil {
+		t.Fatalf("Merge(nil, nil) = nil, want non-nil Config")
+	}
+	if got := Merge(&Config{Port: 1}, nil); got.Port != 1 {
+		t.Errorf("Merge(base, nil).Port = %v, want 1", got.Port)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FilterConfig describes a post-merge filter/rename pipeline applied to the
+// proxy list before proxy-groups are generated.
+type FilterConfig struct {
+	IncludeRegex string       `yaml:"include-regex,omitempty"`
+	ExcludeRegex string       `yaml:"exclude-regex,omitempty"`
+	Rename       []RenameRule `yaml:"rename,omitempty"`
+	Dedup        bool         `yaml:"dedup,omitempty"`
+	Sort         string       `yaml:"sort,omitempty"`
+}
+
+// RenameRule rewrites proxy names matching Match (a regex) to Replace,
+// using Go's regexp.ReplaceAll substitution syntax (e.g. "$1").
+type RenameRule struct {
+	Match   string `yaml:"match"`
+	Replace string `yaml:"replace"`
+}
+
+// loadFilterConfig reads a FilterConfig from a YAML file.
+func loadFilterConfig(path string) (*FilterConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading filter config %s: %w", path, err)
+	}
+
+	var cfg FilterConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing filter config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// applyFilter runs include/exclude filtering, renaming, dedup and sorting
+// over proxies, in that order.
+func applyFilter(proxies []Proxy, cfg *FilterConfig) ([]Proxy, error) {
+	var includeRe, excludeRe *regexp.Regexp
+	var err error
+
+	if cfg.IncludeRegex != "" {
+		includeRe, err = regexp.Compile(cfg.IncludeRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include-regex: %w", err)
+		}
+	}
+	if cfg.ExcludeRegex != "" {
+		excludeRe, err = regexp.Compile(cfg.ExcludeRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude-regex: %w", err)
+		}
+	}
+
+	renameRes := make([]*regexp.Regexp, len(cfg.Rename))
+	for i, rule := range cfg.Rename {
+		renameRes[i], err = regexp.Compile(rule.Match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rename rule %q: %w", rule.Match, err)
+		}
+	}
+
+	var result []Proxy
+	for _, p := range proxies {
+		if includeRe != nil && !includeRe.MatchString(p.Name) {
+			continue
+		}
+		if excludeRe != nil && excludeRe.MatchString(p.Name) {
+			continue
+		}
+		for i, re := range renameRes {
+			p.Name = re.ReplaceAllString(p.Name, cfg.Rename[i].Replace)
+		}
+		result = append(result, p)
+	}
+
+	if cfg.Dedup {
+		result = dedupProxies(result)
+	}
+
+	switch cfg.Sort {
+	case "name":
+		sort.SliceStable(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	case "latency", "":
+		// Latency is not measured by this tool; proxies keep source order.
+	default:
+		return nil, fmt.Errorf("unknown sort mode: %s", cfg.Sort)
+	}
+
+	return result, nil
+}
+
+// proxyDedupKey identifies a proxy by (type, server, port, credential),
+// since the same endpoint is often republished under several names.
+func proxyDedupKey(p Proxy) string {
+	credential := p.UUID
+	if credential == "" {
+		credential = p.Password
+	}
+	return strings.Join([]string{p.Type, p.Server, fmt.Sprint(p.Port), credential}, "|")
+}
+
+// dedupProxies drops later proxies that share a dedup key with an earlier
+// one, keeping the first occurrence.
+func dedupProxies(proxies []Proxy) []Proxy {
+	seen := make(map[string]struct{}, len(proxies))
+	result := make([]Proxy, 0, len(proxies))
+	for _, p := range proxies {
+		key := proxyDedupKey(p)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, p)
+	}
+	return result
+}
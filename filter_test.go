@@ -0,0 +1,92 @@
+package main
+
+import "testing"
+
+func TestApplyFilterIncludeExclude(t *testing.T) {
+	proxies := []Proxy{
+		{Name: "HK-01", Type: "ss", Server: "a", Port: 1},
+		{Name: "US-01", Type: "ss", Server: "b", Port: 2},
+		{Name: "Ads-Traffic", Type: "ss", Server: "c", Port: 3},
+	}
+	cfg := &FilterConfig{
+		IncludeRegex: "^(HK|US)-",
+		ExcludeRegex: "Ads",
+	}
+
+	got, err := applyFilter(proxies, cfg)
+	if err != nil {
+		t.Fatalf("applyFilter() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %v, want 2", len(got))
+	}
+}
+
+func TestApplyFilterRename(t *testing.T) {
+	proxies := []Proxy{{Name: "[Provider] HK-01", Type: "ss", Server: "a", Port: 1}}
+	cfg := &FilterConfig{
+		Rename: []RenameRule{{Match: `^\[Provider\] `, Replace: ""}},
+	}
+
+	got, err := applyFilter(proxies, cfg)
+	if err != nil {
+		t.Fatalf("applyFilter() error = %v", err)
+	}
+	if got[0].Name != "HK-01" {
+		t.Errorf("Name = %v, want HK-01", got[0].Name)
+	}
+}
+
+func TestApplyFilterDedup(t *testing.T) {
+	proxies := []Proxy{
+		{Name: "A", Type: "ss", Server: "host", Port: 1, Password: "p"},
+		{Name: "A-dup", Type: "ss", Server: "host", Port: 1, Password: "p"},
+		{Name: "B", Type: "ss", Server: "host2", Port: 1, Password: "p"},
+	}
+	cfg := &FilterConfig{Dedup: true}
+
+	got, err := applyFilter(proxies, cfg)
+	if err != nil {
+		t.Fatalf("applyFilter() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %v, want 2", len(got))
+	}
+	if got[0].Name != "A" {
+		t.Errorf("first kept proxy = %v, want A (first occurrence)", got[0].Name)
+	}
+}
+
+func TestApplyFilterSortByName(t *testing.T) {
+	proxies := []Proxy{
+		{Name: "US-02", Type: "ss", Server: "a", Port: 1},
+		{Name: "HK-01", Type: "ss", Server: "b", Port: 2},
+	}
+	cfg := &FilterConfig{Sort: "name"}
+
+	got, err := applyFilter(proxies, cfg)
+	if err != nil {
+		t.Fatalf("applyFilter() error = %v", err)
+	}
+	if got[0].Name != "HK-01" || got[1].Name != "US-02" {
+		t.Errorf("got order = [%v, %v], want [HK-01, US-02]", got[0].Name, got[1].Name)
+	}
+}
+
+func TestApplyFilterInvalidRegex(t *testing.T) {
+	cfg := &FilterConfig{IncludeRegex: "("}
+	if _, err := applyFilter(nil, cfg); err == nil {
+		t.Errorf("applyFilter() error = nil, want error for invalid regex")
+	}
+}
+
+func TestDedupProxiesPrefersUUIDOverPassword(t *testing.T) {
+	proxies := []Proxy{
+		{Name: "A", Type: "vless", Server: "host", Port: 443, UUID: "u1", Password: "unused"},
+		{Name: "B", Type: "vless", Server: "host", Port: 443, UUID: "u1"},
+	}
+	got := dedupProxies(proxies)
+	if len(got) != 1 {
+		t.Fatalf("len(got) = %v, want 1", len(got))
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	urlTestURL      = "http://www.gstatic.com/generate_204"
+	urlTestInterval = 300
+)
+
+// RegionRule maps a proxy-group name to a regex matched against proxy
+// names, used to bucket proxies into per-region url-test groups.
+type RegionRule struct {
+	Name  string `yaml:"name"`
+	Regex string `yaml:"regex"`
+}
+
+// GroupOptions controls proxy-group and rule generation for subscriptions
+// that don't define their own.
+type GroupOptions struct {
+	Regions []RegionRule `yaml:"regions,omitempty"`
+	Rules   []string     `yaml:"rules,omitempty"`
+}
+
+// defaultGroupOptions returns the built-in region table and rule set.
+func defaultGroupOptions() GroupOptions {
+	return GroupOptions{
+		Regions: []RegionRule{
+			{Name: "HK", Regex: "HK|香港"},
+			{Name: "US", Regex: "US|美国"},
+			{Name: "JP", Regex: "JP|日本"},
+			{Name: "SG", Regex: "SG|新加坡"},
+			{Name: "TW", Regex: "TW|台湾"},
+		},
+		Rules: []string{
+			"RULE-SET,direct,DIRECT",
+			"RULE-SET,reject,REJECT",
+			"RULE-SET,gfw,PROXY",
+			"RULE-SET,cncidr,DIRECT",
+			"MATCH,PROXY",
+		},
+	}
+}
+
+// loadGroupOptions starts from defaultGroupOptions and overlays any fields
+// present in the YAML file at path, leaving defaults for fields it omits.
+func loadGroupOptions(path string) (*GroupOptions, error) {
+	opts := defaultGroupOptions()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading groups config %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &opts); err != nil {
+		return nil, fmt.Errorf("parsing groups config %s: %w", path, err)
+	}
+	return &opts, nil
+}
+
+// generateProxyGroups synthesizes a standard PROXY/region/AUTO/DIRECT/REJECT
+// group set from cfg.Proxies, bucketing proxies into regions by name using
+// opts.Regions. It is meant to be called when a subscription doesn't define
+// its own proxy-groups.
+func generateProxyGroups(cfg *ClashConfig, opts GroupOptions) []ProxyGroup {
+	allNames := make([]string, len(cfg.Proxies))
+	for i, p := range cfg.Proxies {
+		allNames[i] = p.Name
+	}
+
+	var regionGroups []ProxyGroup
+	for _, region := range opts.Regions {
+		re, err := regexp.Compile(region.Regex)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping region %q: invalid regex: %v\n", region.Name, err)
+			continue
+		}
+		var matched []string
+		for _, p := range cfg.Proxies {
+			if re.MatchString(p.Name) {
+				matched = append(matched, p.Name)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		regionGroups = append(regionGroups, ProxyGroup{
+			Name:     region.Name,
+			Type:     "url-test",
+			Proxies:  matched,
+			URL:      urlTestURL,
+			Interval: urlTestInterval,
+		})
+	}
+
+	proxySelectOptions := []string{"AUTO"}
+	for _, g := range regionGroups {
+		proxySelectOptions = append(proxySelectOptions, g.Name)
+	}
+	proxySelectOptions = append(proxySelectOptions, allNames...)
+
+	groups := []ProxyGroup{
+		{
+			Name:    "PROXY",
+			Type:    "select",
+			Proxies: proxySelectOptions,
+		},
+	}
+	groups = append(groups, regionGroups...)
+	groups = append(groups, ProxyGroup{
+		Name:     "AUTO",
+		Type:     "url-test",
+		Proxies:  allNames,
+		URL:      urlTestURL,
+		Interval: urlTestInterval,
+	})
+	groups = append(groups,
+		ProxyGroup{Name: "DIRECT", Type: "select", Proxies: []string{"DIRECT"}},
+		ProxyGroup{Name: "REJECT", Type: "select", Proxies: []string{"REJECT"}},
+	)
+
+	return groups
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGenerateProxyGroups(t *testing.T) {
+	cfg := &ClashConfig{
+		Proxies: []Proxy{
+			{Name: "HK-01"},
+			{Name: "US-01"},
+			{Name: "香港 02"},
+			{Name: "Unlabeled"},
+		},
+	}
+
+	groups := generateProxyGroups(cfg, defaultGroupOptions())
+
+	byName := make(map[string]ProxyGroup, len(groups))
+	for _, g := range groups {
+		byName[g.Name] = g
+	}
+
+	proxy, ok := byName["PROXY"]
+	if !ok || proxy.Type != "select" {
+		t.Fatalf("PROXY group = %+v, ok %v, want select group", proxy, ok)
+	}
+
+	hk, ok := byName["HK"]
+	if !ok {
+		t.Fatalf("HK group missing")
+	}
+	if hk.Type != "url-test" || len(hk.Proxies) != 2 {
+		t.Errorf("HK group = %+v, want url-test with 2 proxies", hk)
+	}
+
+	if _, ok := byName["JP"]; ok {
+		t.Errorf("JP group present despite no matching proxies")
+	}
+
+	auto, ok := byName["AUTO"]
+	if !ok || auto.Type != "url-test" || len(auto.Proxies) != 4 {
+		t.Errorf("AUTO group = %+v, ok %v, want url-test over all 4 proxies", auto, ok)
+	}
+
+	if g, ok := byName["DIRECT"]; !ok || g.Type != "select" {
+		t.Errorf("DIRECT group = %+v, ok %v, want select group", g, ok)
+	}
+	if g, ok := byName["REJECT"]; !ok || g.Type != "select" {
+		t.Errorf("REJECT group = %+v, ok %v, want select group", g, ok)
+	}
+}
+
+func TestDefaultGroupOptionsRules(t *testing.T) {
+	opts := defaultGroupOptions()
+	if len(opts.Rules) != 5 {
+		t.Fatalf("len(Rules) = %v, want 5", len(opts.Rules))
+	}
+	if opts.Rules[len(opts.Rules)-1] != "MATCH,PROXY" {
+		t.Errorf("last rule = %v, want MATCH,PROXY", opts.Rules[len(opts.Rules)-1])
+	}
+}
+
+func TestLoadGroupOptionsOverlay(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/groups.yaml"
+	content := "regions:\n  - name: EU\n    regex: \"EU|欧洲\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+
+	opts, err := loadGroupOptions(path)
+	if err != nil {
+		t.Fatalf("loadGroupOptions() error = %v", err)
+	}
+	if len(opts.Regions) != 1 || opts.Regions[0].Name != "EU" {
+		t.Errorf("Regions = %+v, want only EU", opts.Regions)
+	}
+	if len(opts.Rules) != 5 {
+		t.Errorf("Rules = %+v, want default 5 rules preserved", opts.Rules)
+	}
+}
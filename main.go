@@ -7,64 +7,35 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"reflect"
 	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
-)
-
-type ClashConfig struct {
-	Port               int                     `yaml:"port"`
-	SocksPort          int                     `yaml:"socks-port"`
-	RedirPort          int                     `yaml:"redir-port"`
-	MixedPort          int                     `yaml:"mixed-port"`
-	AllowLan           bool                    `yaml:"allow-lan"`
-	BindAddress        string                  `yaml:"bind-address"`
-	Mode               string                  `yaml:"mode"`
-	LogLevel           string                  `yaml:"log-level"`
-	ExternalController string                  `yaml:"external-controller"`
-	Proxies            []Proxy                 `yaml:"proxies"`
-	ProxyGroups        []ProxyGroup            `yaml:"proxy-groups"`
-	Rules              []string                `yaml:"rules"`
-	RuleProviders      map[string]RuleProvider `yaml:"rule-providers,omitempty"`
-}
 
-type Proxy struct {
-	Name           string         `yaml:"name"`
-	Type           string         `yaml:"type"`
-	Server         string         `yaml:"server"`
-	Port           int            `yaml:"port"`
-	Password       string         `yaml:"password,omitempty"`
-	UUID           string         `yaml:"uuid,omitempty"`
-	Cipher         string         `yaml:"cipher,omitempty"`
-	Network        string         `yaml:"network,omitempty"`
-	UDP            bool           `yaml:"udp,omitempty"`
-	TLS            bool           `yaml:"tls,omitempty"`
-	SkipCertVerify bool           `yaml:"skip-cert-verify,omitempty"`
-	Extra          map[string]any `yaml:",inline,omitempty"`
-}
-
-type ProxyGroup struct {
-	Name     string   `yaml:"name"`
-	Type     string   `yaml:"type"`
-	Proxies  []string `yaml:"proxies"`
-	URL      string   `yaml:"url,omitempty"`
-	Interval int      `yaml:"interval,omitempty"`
-}
+	"github.com/wallacegibbon/proxy-config-updater/clashconfig"
+	"github.com/wallacegibbon/proxy-config-updater/parsers"
+)
 
-type RuleProvider struct {
-	Type     string `yaml:"type"`
-	Behavior string `yaml:"behavior"`
-	Format   string `yaml:"format,omitempty"`
-	URL      string `yaml:"url"`
-	Path     string `yaml:"path"`
-	Interval int    `yaml:"interval"`
-}
+// ClashConfig, Proxy, ProxyGroup and RuleProvider alias the clashconfig
+// package's typed schema so the rest of this package doesn't need to
+// qualify every reference to them.
+type (
+	ClashConfig  = clashconfig.Config
+	Proxy        = clashconfig.Proxy
+	ProxyGroup   = clashconfig.ProxyGroup
+	RuleProvider = clashconfig.RuleProvider
+)
 
 func main() {
 	var output string
 	var pretty bool = true
+	var sourcesFlag string
+	var filterPath string
+	var groupsConfigPath string
+	var serveAddr string
+	var serveConfigPath string
+	var cacheTTL time.Duration = 10 * time.Minute
+	var tlsCert, tlsKey string
 	urlFile := ""
 
 	// Manual flag parsing to allow flags anywhere
@@ -81,6 +52,75 @@ func main() {
 					fmt.Fprintln(os.Stderr, "Error: -output requires a value")
 					os.Exit(1)
 				}
+			case "-sources":
+				if i+1 < len(args) {
+					sourcesFlag = args[i+1]
+					i++
+				} else {
+					fmt.Fprintln(os.Stderr, "Error: -sources requires a value")
+					os.Exit(1)
+				}
+			case "-filter":
+				if i+1 < len(args) {
+					filterPath = args[i+1]
+					i++
+				} else {
+					fmt.Fprintln(os.Stderr, "Error: -filter requires a value")
+					os.Exit(1)
+				}
+			case "-groups-config":
+				if i+1 < len(args) {
+					groupsConfigPath = args[i+1]
+					i++
+				} else {
+					fmt.Fprintln(os.Stderr, "Error: -groups-config requires a value")
+					os.Exit(1)
+				}
+			case "-serve":
+				if i+1 < len(args) {
+					serveAddr = args[i+1]
+					i++
+				} else {
+					fmt.Fprintln(os.Stderr, "Error: -serve requires a value")
+					os.Exit(1)
+				}
+			case "-serve-config":
+				if i+1 < len(args) {
+					serveConfigPath = args[i+1]
+					i++
+				} else {
+					fmt.Fprintln(os.Stderr, "Error: -serve-config requires a value")
+					os.Exit(1)
+				}
+			case "-cache-ttl":
+				if i+1 < len(args) {
+					parsed, err := time.ParseDuration(args[i+1])
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Error: invalid -cache-ttl: %v\n", err)
+						os.Exit(1)
+					}
+					cacheTTL = parsed
+					i++
+				} else {
+					fmt.Fprintln(os.Stderr, "Error: -cache-ttl requires a value")
+					os.Exit(1)
+				}
+			case "-listen-tls-cert":
+				if i+1 < len(args) {
+					tlsCert = args[i+1]
+					i++
+				} else {
+					fmt.Fprintln(os.Stderr, "Error: -listen-tls-cert requires a value")
+					os.Exit(1)
+				}
+			case "-listen-tls-key":
+				if i+1 < len(args) {
+					tlsKey = args[i+1]
+					i++
+				} else {
+					fmt.Fprintln(os.Stderr, "Error: -listen-tls-key requires a value")
+					os.Exit(1)
+				}
 			case "-pretty":
 				pretty = true
 			case "-pretty=false":
@@ -97,43 +137,46 @@ func main() {
 		}
 	}
 
-	if urlFile == "" {
+	if serveAddr != "" {
+		defaultSource := serveSource{Sources: []string{}, Filter: filterPath, GroupsConfig: groupsConfigPath}
+		if sources, err := resolveSources(urlFile, sourcesFlag); err == nil {
+			defaultSource.Sources = sources
+		}
+		if err := runServer(serveAddr, serveConfigPath, defaultSource, cacheTTL, tlsCert, tlsKey); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if urlFile == "" && sourcesFlag == "" {
 		fmt.Fprintln(os.Stderr, "Usage: base64-subscription-config <url-file> [options]")
 		fmt.Fprintln(os.Stderr, "  -output string   Output file path (default: stdout)")
 		fmt.Fprintln(os.Stderr, "  -pretty          Pretty print output (default true)")
-		os.Exit(1)
-	}
-	urlBytes, err := os.ReadFile(urlFile)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading URL file: %v\n", err)
-		os.Exit(1)
-	}
-
-	subscriptionURL := strings.TrimSpace(string(urlBytes))
-	if subscriptionURL == "" {
-		fmt.Fprintln(os.Stderr, "Error: URL file is empty")
+		fmt.Fprintln(os.Stderr, "  -sources string  Comma-separated list of URL-files or inline URLs")
+		fmt.Fprintln(os.Stderr, "  -filter string   Filter/rename pipeline config (YAML)")
+		fmt.Fprintln(os.Stderr, "  -groups-config string  Proxy-group/rule generation config (YAML)")
+		fmt.Fprintln(os.Stderr, "  -serve string    Start an HTTP server instead of writing once, e.g. :8080")
+		fmt.Fprintln(os.Stderr, "  -serve-config string  Named sources config for -serve (YAML)")
+		fmt.Fprintln(os.Stderr, "  -cache-ttl duration  How long -serve caches a generated config (default 10m)")
 		os.Exit(1)
 	}
 
-	fmt.Fprintf(os.Stderr, "Reading URL from file: %s\n", urlFile)
-	fmt.Fprintf(os.Stderr, "Fetching subscription from: %s\n", subscriptionURL)
-	encodedContent, err := fetchContent(subscriptionURL)
+	sources, err := resolveSources(urlFile, sourcesFlag)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error fetching content: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Fprintln(os.Stderr, "Decoding base64 content...")
-	decodedContent, err := decodeBase64(encodedContent)
+	fmt.Fprintf(os.Stderr, "Fetching %d subscription source(s)...\n", len(sources))
+	config, _, err := fetchSources(sources)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: Content is not base64 encoded, trying raw YAML...\n")
-		decodedContent = encodedContent
+		fmt.Fprintf(os.Stderr, "Error fetching sources: %v\n", err)
+		os.Exit(1)
 	}
 
-	fmt.Fprintln(os.Stderr, "Parsing Clash configuration...")
-	config, err := parseClashConfig(decodedContent)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing config: %v\n", err)
+	if err := applyFilterAndGroups(config, filterPath, groupsConfigPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -170,40 +213,47 @@ func main() {
 		}
 		outputWriter.Write(yamlData)
 	} else {
-		outputWriter.Write([]byte(decodedContent))
+		yamlData, err := yaml.Marshal(config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling YAML: %v\n", err)
+			os.Exit(1)
+		}
+		outputWriter.Write(yamlData)
 	}
 
 	fmt.Fprintln(os.Stderr, "\nDone!")
 }
 
-func fetchContent(url string) (string, error) {
+// fetchContent fetches url and returns its body along with the response
+// headers, so callers can read upstream headers like Subscription-Userinfo.
+func fetchContent(url string) (string, http.Header, error) {
 	client := &http.Client{
 		Timeout: 30 * time.Second,
 	}
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	req.Header.Set("User-Agent", "ClashSubscriptionParser/1.0")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		return "", nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
 	}
 
 	content, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	return string(content), nil
+	return string(content), resp.Header, nil
 }
 
 func decodeBase64(encoded string) (string, error) {
@@ -270,6 +320,115 @@ func parseClashConfig(content string) (*ClashConfig, error) {
 	return &config, nil
 }
 
+// parseURIList parses a line-delimited list of ss://, vmess://, trojan://,
+// vless:// and hysteria2:// URIs into proxies. Lines that fail to parse are
+// skipped with a warning rather than failing the whole run.
+func parseURIList(content string) ([]Proxy, error) {
+	var proxies []Proxy
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		parsed, err := parsers.Parse(line)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping unparseable proxy URI: %v\n", err)
+			continue
+		}
+
+		proxies = append(proxies, toClashProxy(parsed))
+	}
+
+	return proxies, nil
+}
+
+// toClashProxy converts a parsers.ParsedProxy into the typed clashconfig
+// schema, promoting known Extra keys (set by the parsers package) into
+// their proper typed fields and leaving the rest inline.
+func toClashProxy(parsed *parsers.ParsedProxy) Proxy {
+	p := Proxy{
+		Name:           parsed.Name,
+		Type:           parsed.Type,
+		Server:         parsed.Server,
+		Port:           parsed.Port,
+		Password:       parsed.Password,
+		UUID:           parsed.UUID,
+		Cipher:         parsed.Cipher,
+		Network:        parsed.Network,
+		UDP:            parsed.UDP,
+		TLS:            parsed.TLS,
+		SkipCertVerify: parsed.SkipCertVerify,
+		Extra:          map[string]any{},
+	}
+
+	for key, value := range parsed.Extra {
+		switch key {
+		case "sni":
+			p.SNI, _ = value.(string)
+		case "servername":
+			p.ServerName, _ = value.(string)
+		case "flow":
+			p.Flow, _ = value.(string)
+		case "plugin":
+			p.Plugin, _ = value.(string)
+		case "plugin-opts":
+			if opts, ok := value.(map[string]any); ok {
+				p.PluginOpts = opts
+			}
+		case "alterId":
+			if aid, ok := value.(int); ok {
+				p.AlterID = aid
+			}
+		case "alpn":
+			if alpn, ok := value.([]string); ok {
+				p.ALPN = alpn
+			}
+		case "ws-opts":
+			if opts, ok := value.(map[string]any); ok {
+				p.WSOpts = toWSOpts(opts)
+			}
+		case "grpc-opts":
+			if opts, ok := value.(map[string]any); ok {
+				if name, ok := opts["grpc-service-name"].(string); ok {
+					p.GrpcOpts = &clashconfig.GrpcOpts{GrpcServiceName: name}
+				}
+			}
+		case "reality-opts":
+			if opts, ok := value.(map[string]any); ok {
+				realityOpts := &clashconfig.RealityOpts{}
+				realityOpts.PublicKey, _ = opts["public-key"].(string)
+				realityOpts.ShortID, _ = opts["short-id"].(string)
+				p.RealityOpts = realityOpts
+			}
+		default:
+			p.Extra[key] = value
+		}
+	}
+	if len(p.Extra) == 0 {
+		p.Extra = nil
+	}
+
+	return p
+}
+
+func toWSOpts(opts map[string]any) *clashconfig.WSOpts {
+	ws := &clashconfig.WSOpts{}
+	if path, ok := opts["path"].(string); ok {
+		ws.Path = path
+	}
+	if headers, ok := opts["headers"].(map[string]any); ok {
+		ws.Headers = make(map[string]string, len(headers))
+		for k, v := range headers {
+			if s, ok := v.(string); ok {
+				ws.Headers[k] = s
+			}
+		}
+	}
+	return ws
+}
+
 func loadDefaultConfig() (*ClashConfig, error) {
 	return &ClashConfig{
 		Port:      7890,
@@ -315,27 +474,5 @@ func loadDefaultConfig() (*ClashConfig, error) {
 }
 
 func mergeConfigs(defaultConfig, subscriptionConfig *ClashConfig) *ClashConfig {
-	// Start with default config
-	merged := *defaultConfig
-
-	// Overwrite with subscription config fields if they are non-zero
-	// For simplicity, we'll just unmarshal subscription into default
-	// But we need to preserve default values for fields not in subscription
-	// We'll use reflection to copy non-zero fields from subscription to default
-
-	subVal := reflect.ValueOf(subscriptionConfig).Elem()
-	defVal := reflect.ValueOf(&merged).Elem()
-
-	for i := 0; i < subVal.NumField(); i++ {
-		subField := subVal.Field(i)
-		defField := defVal.Field(i)
-
-		// Check if the field is zero in subscription config
-		// If not zero, copy to default
-		if !subField.IsZero() {
-			defField.Set(subField)
-		}
-	}
-
-	return &merged
+	return clashconfig.Merge(defaultConfig, subscriptionConfig)
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/base64"
 	"testing"
 )
 
@@ -234,6 +235,25 @@ func TestMergeConfigs(t *testing.T) {
 	}
 }
 
+func TestParseURIList(t *testing.T) {
+	userinfo := base64.StdEncoding.EncodeToString([]byte("aes-256-gcm:secret"))
+	content := "ss://" + userinfo + "@example.com:8388#Node1\n\nnot-a-uri\ntrojan://secret@trojan.example.com:443#Node2\n"
+
+	proxies, err := parseURIList(content)
+	if err != nil {
+		t.Fatalf("parseURIList() error = %v", err)
+	}
+	if len(proxies) != 2 {
+		t.Fatalf("len(proxies) = %v, want 2", len(proxies))
+	}
+	if proxies[0].Type != "ss" || proxies[0].Name != "Node1" {
+		t.Errorf("proxies[0] = %+v, want type ss name Node1", proxies[0])
+	}
+	if proxies[1].Type != "trojan" || proxies[1].Name != "Node2" {
+		t.Errorf("proxies[1] = %+v, want type trojan name Node2", proxies[1])
+	}
+}
+
 func TestLoadDefaultConfig(t *testing.T) {
 	config, err := loadDefaultConfig()
 	if err != nil {
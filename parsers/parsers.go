@@ -0,0 +1,411 @@
+// Package parsers decodes line-delimited proxy URIs (ss://, vmess://,
+// trojan://, vless://, hysteria2://) into a protocol-agnostic ParsedProxy,
+// for subscriptions that ship a base64 URI list instead of Clash YAML.
+package parsers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ParsedProxy is a protocol-agnostic view of a single proxy URI. Its fields
+// mirror the core fields of the Clash proxy schema; anything else goes in
+// Extra so it can be re-serialized without being dropped.
+type ParsedProxy struct {
+	Name           string
+	Type           string
+	Server         string
+	Port           int
+	Password       string
+	UUID           string
+	Cipher         string
+	Network        string
+	UDP            bool
+	TLS            bool
+	SkipCertVerify bool
+	Extra          map[string]any
+}
+
+// Parse dispatches a single URI line to the parser for its scheme.
+func Parse(line string) (*ParsedProxy, error) {
+	switch {
+	case strings.HasPrefix(line, "ss://"):
+		return parseSS(line)
+	case strings.HasPrefix(line, "vmess://"):
+		return parseVMess(line)
+	case strings.HasPrefix(line, "trojan://"):
+		return parseTrojan(line)
+	case strings.HasPrefix(line, "vless://"):
+		return parseVLESS(line)
+	case strings.HasPrefix(line, "hysteria2://"):
+		return parseHysteria2(line)
+	default:
+		return nil, fmt.Errorf("unrecognized URI scheme: %s", line)
+	}
+}
+
+// Recognized reports whether content looks like a line-delimited URI
+// subscription rather than Clash YAML, based on its first non-empty line.
+func Recognized(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		for _, scheme := range []string{"ss://", "vmess://", "trojan://", "vless://", "hysteria2://"} {
+			if strings.HasPrefix(line, scheme) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func parseSS(line string) (*ParsedProxy, error) {
+	u, err := url.Parse(line)
+	if err != nil {
+		return nil, fmt.Errorf("ss: invalid URI: %w", err)
+	}
+	if u.User == nil || u.Hostname() == "" || u.Port() == "" {
+		return nil, fmt.Errorf("ss: missing userinfo or host:port")
+	}
+
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return nil, fmt.Errorf("ss: invalid port: %w", err)
+	}
+
+	decoded, err := decodeB64(u.User.Username())
+	if err != nil {
+		return nil, fmt.Errorf("ss: invalid base64 userinfo: %w", err)
+	}
+	method, password, ok := strings.Cut(decoded, ":")
+	if !ok {
+		return nil, fmt.Errorf("ss: userinfo missing method:password separator")
+	}
+
+	p := &ParsedProxy{
+		Name:     proxyName(u, u.Hostname()),
+		Type:     "ss",
+		Server:   u.Hostname(),
+		Port:     port,
+		Password: password,
+		Cipher:   method,
+		UDP:      true,
+		Extra:    map[string]any{},
+	}
+	if plugin := u.Query().Get("plugin"); plugin != "" {
+		name, opts := parsePluginSpec(plugin)
+		p.Extra["plugin"] = name
+		if len(opts) > 0 {
+			p.Extra["plugin-opts"] = opts
+		}
+	}
+	return p, nil
+}
+
+func parseVMess(line string) (*ParsedProxy, error) {
+	encoded := strings.TrimPrefix(line, "vmess://")
+	if hash := strings.IndexByte(encoded, '#'); hash != -1 {
+		encoded = encoded[:hash]
+	}
+	decoded, err := decodeB64(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("vmess: invalid base64 payload: %w", err)
+	}
+
+	var raw struct {
+		PS   string      `json:"ps"`
+		Add  string      `json:"add"`
+		Port json.Number `json:"port"`
+		ID   string      `json:"id"`
+		Aid  json.Number `json:"aid"`
+		Net  string      `json:"net"`
+		Type string      `json:"type"`
+		Host string      `json:"host"`
+		Path string      `json:"path"`
+		TLS  string      `json:"tls"`
+		SNI  string      `json:"sni"`
+		ALPN string      `json:"alpn"`
+	}
+	if err := json.Unmarshal([]byte(decoded), &raw); err != nil {
+		return nil, fmt.Errorf("vmess: invalid JSON payload: %w", err)
+	}
+	if raw.Add == "" || raw.ID == "" {
+		return nil, fmt.Errorf("vmess: missing add or id")
+	}
+
+	port, err := raw.Port.Int64()
+	if err != nil {
+		return nil, fmt.Errorf("vmess: invalid port: %w", err)
+	}
+	aid, _ := raw.Aid.Int64()
+
+	name := raw.PS
+	if name == "" {
+		name = raw.Add
+	}
+
+	p := &ParsedProxy{
+		Name:    name,
+		Type:    "vmess",
+		Server:  raw.Add,
+		Port:    int(port),
+		UUID:    raw.ID,
+		Network: raw.Net,
+		TLS:     raw.TLS == "tls",
+		UDP:     true,
+		Extra: map[string]any{
+			"alterId": int(aid),
+		},
+	}
+	if raw.SNI != "" {
+		p.Extra["servername"] = raw.SNI
+	}
+	if alpn := parseALPN(raw.ALPN); alpn != nil {
+		p.Extra["alpn"] = alpn
+	}
+	switch raw.Net {
+	case "ws":
+		p.Extra["ws-opts"] = map[string]any{
+			"path":    raw.Path,
+			"headers": map[string]any{"Host": raw.Host},
+		}
+	case "grpc":
+		p.Extra["grpc-opts"] = map[string]any{
+			"grpc-service-name": raw.Path,
+		}
+	}
+	return p, nil
+}
+
+func parseTrojan(line string) (*ParsedProxy, error) {
+	u, err := url.Parse(line)
+	if err != nil {
+		return nil, fmt.Errorf("trojan: invalid URI: %w", err)
+	}
+	if u.User == nil || u.Hostname() == "" || u.Port() == "" {
+		return nil, fmt.Errorf("trojan: missing password or host:port")
+	}
+
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return nil, fmt.Errorf("trojan: invalid port: %w", err)
+	}
+
+	q := u.Query()
+	p := &ParsedProxy{
+		Name:     proxyName(u, u.Hostname()),
+		Type:     "trojan",
+		Server:   u.Hostname(),
+		Port:     port,
+		Password: u.User.Username(),
+		Network:  q.Get("type"),
+		TLS:      true,
+		UDP:      true,
+		Extra:    map[string]any{},
+	}
+	if sni := q.Get("sni"); sni != "" {
+		p.Extra["sni"] = sni
+	}
+	if alpn := parseALPN(q.Get("alpn")); alpn != nil {
+		p.Extra["alpn"] = alpn
+	}
+	addTransportOpts(p, q)
+	return p, nil
+}
+
+func parseVLESS(line string) (*ParsedProxy, error) {
+	u, err := url.Parse(line)
+	if err != nil {
+		return nil, fmt.Errorf("vless: invalid URI: %w", err)
+	}
+	if u.User == nil || u.Hostname() == "" || u.Port() == "" {
+		return nil, fmt.Errorf("vless: missing uuid or host:port")
+	}
+
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return nil, fmt.Errorf("vless: invalid port: %w", err)
+	}
+
+	q := u.Query()
+	security := q.Get("security")
+
+	var pbk string
+	if security == "reality" {
+		pbk = q.Get("pbk")
+		if pbk == "" {
+			return nil, fmt.Errorf("vless: reality node missing pbk (public key)")
+		}
+	}
+
+	p := &ParsedProxy{
+		Name:    proxyName(u, u.Hostname()),
+		Type:    "vless",
+		Server:  u.Hostname(),
+		Port:    port,
+		UUID:    u.User.Username(),
+		Network: q.Get("type"),
+		TLS:     security == "tls" || security == "reality",
+		UDP:     true,
+		Extra:   map[string]any{},
+	}
+	if flow := q.Get("flow"); flow != "" {
+		p.Extra["flow"] = flow
+	}
+	if sni := q.Get("sni"); sni != "" {
+		p.Extra["servername"] = sni
+	}
+	if alpn := parseALPN(q.Get("alpn")); alpn != nil {
+		p.Extra["alpn"] = alpn
+	}
+	if security == "reality" {
+		realityOpts := map[string]any{"public-key": pbk}
+		if sid := q.Get("sid"); sid != "" {
+			realityOpts["short-id"] = sid
+		}
+		p.Extra["reality-opts"] = realityOpts
+	}
+	addTransportOpts(p, q)
+	return p, nil
+}
+
+func parseHysteria2(line string) (*ParsedProxy, error) {
+	u, err := url.Parse(line)
+	if err != nil {
+		return nil, fmt.Errorf("hysteria2: invalid URI: %w", err)
+	}
+	if u.Hostname() == "" || u.Port() == "" {
+		return nil, fmt.Errorf("hysteria2: missing host:port")
+	}
+
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return nil, fmt.Errorf("hysteria2: invalid port: %w", err)
+	}
+
+	password := u.User.Username()
+	q := u.Query()
+	p := &ParsedProxy{
+		Name:           proxyName(u, u.Hostname()),
+		Type:           "hysteria2",
+		Server:         u.Hostname(),
+		Port:           port,
+		Password:       password,
+		TLS:            true,
+		UDP:            true,
+		SkipCertVerify: q.Get("insecure") == "1",
+		Extra:          map[string]any{},
+	}
+	if sni := q.Get("sni"); sni != "" {
+		p.Extra["sni"] = sni
+	}
+	if alpn := parseALPN(q.Get("alpn")); alpn != nil {
+		p.Extra["alpn"] = alpn
+	}
+	return p, nil
+}
+
+// addTransportOpts fills in p.Extra's ws-opts or grpc-opts from a trojan/
+// vless query string, keyed off p.Network (the URI's `type` param), mirroring
+// parseVMess's net-based switch so a grpc node doesn't get a bogus ws-opts.
+func addTransportOpts(p *ParsedProxy, q url.Values) {
+	switch p.Network {
+	case "ws", "http":
+		if host := q.Get("host"); host != "" || q.Get("path") != "" {
+			p.Extra["ws-opts"] = map[string]any{
+				"path":    q.Get("path"),
+				"headers": map[string]any{"Host": host},
+			}
+		}
+	case "grpc":
+		serviceName := q.Get("serviceName")
+		if serviceName == "" {
+			serviceName = q.Get("path")
+		}
+		if serviceName != "" {
+			p.Extra["grpc-opts"] = map[string]any{
+				"grpc-service-name": serviceName,
+			}
+		}
+	}
+}
+
+// parsePluginSpec splits a shadowsocks `plugin` query value (e.g.
+// "obfs-local;obfs=http;obfs-host=x") into the plugin name and its
+// semicolon-separated key=value options. It does not translate plugin or
+// option names to mihomo's own aliases (e.g. "obfs-local" -> "obfs"), so
+// callers that need mihomo-compatible plugin-opts keys must still map them.
+func parsePluginSpec(raw string) (string, map[string]any) {
+	parts := strings.Split(raw, ";")
+	name := parts[0]
+	if len(parts) == 1 {
+		return name, nil
+	}
+
+	opts := make(map[string]any, len(parts)-1)
+	for _, part := range parts[1:] {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			opts[part] = true
+			continue
+		}
+		opts[key] = value
+	}
+	return name, opts
+}
+
+// parseALPN splits a comma-separated alpn value (from a query string or a
+// vmess JSON payload) into its parts, trimming whitespace and dropping
+// empties.
+func parseALPN(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var alpn []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			alpn = append(alpn, part)
+		}
+	}
+	return alpn
+}
+
+// proxyName returns the URI fragment (proxy name) or, if absent, fallback.
+func proxyName(u *url.URL, fallback string) string {
+	if u.Fragment != "" {
+		return u.Fragment
+	}
+	return fallback
+}
+
+// decodeB64 decodes a base64 blob trying standard, raw-standard, URL and
+// raw-URL alphabets in turn, since URI subscriptions are inconsistent about
+// padding and alphabet.
+func decodeB64(s string) (string, error) {
+	encodings := []*base64.Encoding{
+		base64.StdEncoding,
+		base64.RawStdEncoding,
+		base64.URLEncoding,
+		base64.RawURLEncoding,
+	}
+
+	var firstErr error
+	for _, enc := range encodings {
+		decoded, err := enc.DecodeString(s)
+		if err == nil {
+			return string(decoded), nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return "", firstErr
+}
@@ -0,0 +1,229 @@
+package parsers
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestParseSS(t *testing.T) {
+	userinfo := base64.StdEncoding.EncodeToString([]byte("aes-256-gcm:secret"))
+	line := "ss://" + userinfo + "@example.com:8388?plugin=obfs-local#My%20Node"
+
+	p, err := Parse(line)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if p.Type != "ss" {
+		t.Errorf("Type = %v, want ss", p.Type)
+	}
+	if p.Server != "example.com" || p.Port != 8388 {
+		t.Errorf("Server:Port = %v:%v, want example.com:8388", p.Server, p.Port)
+	}
+	if p.Cipher != "aes-256-gcm" || p.Password != "secret" {
+		t.Errorf("Cipher/Password = %v/%v, want aes-256-gcm/secret", p.Cipher, p.Password)
+	}
+	if p.Name != "My Node" {
+		t.Errorf("Name = %v, want My Node", p.Name)
+	}
+	if p.Extra["plugin"] != "obfs-local" {
+		t.Errorf("Extra[plugin] = %v, want obfs-local", p.Extra["plugin"])
+	}
+}
+
+func TestParseSSPluginOpts(t *testing.T) {
+	userinfo := base64.StdEncoding.EncodeToString([]byte("aes-256-gcm:secret"))
+	line := "ss://" + userinfo + "@example.com:8388?plugin=obfs-local%3Bobfs%3Dhttp%3Bobfs-host%3Dx#n"
+
+	p, err := Parse(line)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if p.Extra["plugin"] != "obfs-local" {
+		t.Errorf("Extra[plugin] = %v, want obfs-local", p.Extra["plugin"])
+	}
+	opts, ok := p.Extra["plugin-opts"].(map[string]any)
+	if !ok {
+		t.Fatalf("Extra[plugin-opts] = %v, want map[string]any", p.Extra["plugin-opts"])
+	}
+	if opts["obfs"] != "http" || opts["obfs-host"] != "x" {
+		t.Errorf("plugin-opts = %v, want obfs=http obfs-host=x", opts)
+	}
+}
+
+func TestParseVMess(t *testing.T) {
+	payload := `{"ps":"node-a","add":"vm.example.com","port":"443","id":"uuid-1","aid":"0","net":"ws","host":"vm.example.com","path":"/ray","tls":"tls"}`
+	line := "vmess://" + base64.StdEncoding.EncodeToString([]byte(payload))
+
+	p, err := Parse(line)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if p.Type != "vmess" {
+		t.Errorf("Type = %v, want vmess", p.Type)
+	}
+	if p.Server != "vm.example.com" || p.Port != 443 {
+		t.Errorf("Server:Port = %v:%v, want vm.example.com:443", p.Server, p.Port)
+	}
+	if p.UUID != "uuid-1" {
+		t.Errorf("UUID = %v, want uuid-1", p.UUID)
+	}
+	if !p.TLS {
+		t.Errorf("TLS = false, want true")
+	}
+	if _, ok := p.Extra["ws-opts"]; !ok {
+		t.Errorf("Extra[ws-opts] missing")
+	}
+}
+
+func TestParseTrojan(t *testing.T) {
+	line := "trojan://secret@trojan.example.com:443?sni=trojan.example.com&type=ws&host=trojan.example.com&path=%2Fray#TJ"
+
+	p, err := Parse(line)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if p.Type != "trojan" || p.Password != "secret" {
+		t.Errorf("Type/Password = %v/%v, want trojan/secret", p.Type, p.Password)
+	}
+	if p.Name != "TJ" {
+		t.Errorf("Name = %v, want TJ", p.Name)
+	}
+	if !p.TLS {
+		t.Errorf("TLS = false, want true")
+	}
+}
+
+func TestParseTrojanGRPC(t *testing.T) {
+	line := "trojan://secret@trojan.example.com:443?sni=trojan.example.com&type=grpc&serviceName=ray&alpn=h2,http%2F1.1#TJ"
+
+	p, err := Parse(line)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if _, ok := p.Extra["ws-opts"]; ok {
+		t.Errorf("Extra[ws-opts] set for grpc transport, want unset")
+	}
+	opts, ok := p.Extra["grpc-opts"].(map[string]any)
+	if !ok {
+		t.Fatalf("Extra[grpc-opts] = %v, want map[string]any", p.Extra["grpc-opts"])
+	}
+	if opts["grpc-service-name"] != "ray" {
+		t.Errorf("grpc-service-name = %v, want ray", opts["grpc-service-name"])
+	}
+	alpn, ok := p.Extra["alpn"].([]string)
+	if !ok || len(alpn) != 2 || alpn[0] != "h2" || alpn[1] != "http/1.1" {
+		t.Errorf("Extra[alpn] = %v, want [h2 http/1.1]", p.Extra["alpn"])
+	}
+}
+
+func TestParseVLESS(t *testing.T) {
+	line := "vless://11111111-2222-3333-4444-555555555555@vless.example.com:443?encryption=none&security=tls&type=ws&host=vless.example.com&path=%2Fray&sni=vless.example.com#VL"
+
+	p, err := Parse(line)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if p.Type != "vless" || p.UUID != "11111111-2222-3333-4444-555555555555" {
+		t.Errorf("Type/UUID = %v/%v", p.Type, p.UUID)
+	}
+	if !p.TLS {
+		t.Errorf("TLS = false, want true")
+	}
+	if p.Extra["servername"] != "vless.example.com" {
+		t.Errorf("Extra[servername] = %v, want vless.example.com", p.Extra["servername"])
+	}
+}
+
+func TestParseVLESSGRPC(t *testing.T) {
+	line := "vless://11111111-2222-3333-4444-555555555555@vless.example.com:443?encryption=none&security=tls&type=grpc&serviceName=ray#VL"
+
+	p, err := Parse(line)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if _, ok := p.Extra["ws-opts"]; ok {
+		t.Errorf("Extra[ws-opts] set for grpc transport, want unset")
+	}
+	opts, ok := p.Extra["grpc-opts"].(map[string]any)
+	if !ok || opts["grpc-service-name"] != "ray" {
+		t.Errorf("Extra[grpc-opts] = %v, want grpc-service-name=ray", p.Extra["grpc-opts"])
+	}
+}
+
+func TestParseVLESSReality(t *testing.T) {
+	line := "vless://11111111-2222-3333-4444-555555555555@vless.example.com:443?encryption=none&security=reality&type=tcp&pbk=pubkey123&sid=abcd#VL"
+
+	p, err := Parse(line)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	opts, ok := p.Extra["reality-opts"].(map[string]any)
+	if !ok {
+		t.Fatalf("Extra[reality-opts] = %v, want map[string]any", p.Extra["reality-opts"])
+	}
+	if opts["public-key"] != "pubkey123" || opts["short-id"] != "abcd" {
+		t.Errorf("reality-opts = %v, want public-key=pubkey123 short-id=abcd", opts)
+	}
+}
+
+func TestParseVLESSRealityMissingPublicKey(t *testing.T) {
+	line := "vless://11111111-2222-3333-4444-555555555555@vless.example.com:443?encryption=none&security=reality&type=tcp#VL"
+
+	if _, err := Parse(line); err == nil {
+		t.Errorf("Parse() error = nil, want error for reality node missing pbk")
+	}
+}
+
+func TestParseHysteria2(t *testing.T) {
+	line := "hysteria2://secret@hy2.example.com:443?sni=hy2.example.com&insecure=1#HY2"
+
+	p, err := Parse(line)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if p.Type != "hysteria2" || p.Password != "secret" {
+		t.Errorf("Type/Password = %v/%v", p.Type, p.Password)
+	}
+	if !p.SkipCertVerify {
+		t.Errorf("SkipCertVerify = false, want true")
+	}
+}
+
+func TestParseHysteria2ALPN(t *testing.T) {
+	line := "hysteria2://secret@hy2.example.com:443?sni=hy2.example.com&alpn=h3#HY2"
+
+	p, err := Parse(line)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	alpn, ok := p.Extra["alpn"].([]string)
+	if !ok || len(alpn) != 1 || alpn[0] != "h3" {
+		t.Errorf("Extra[alpn] = %v, want [h3]", p.Extra["alpn"])
+	}
+}
+
+func TestParseUnknownScheme(t *testing.T) {
+	if _, err := Parse("http://example.com"); err == nil {
+		t.Errorf("Parse() error = nil, want error for unrecognized scheme")
+	}
+}
+
+func TestRecognized(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"ss list", "ss://abc@host:1#n\nvmess://xyz", true},
+		{"blank lines first", "\n\n  \nvless://abc@host:1", true},
+		{"clash yaml", "proxies:\n  - name: a\n", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Recognized(tt.content); got != tt.want {
+				t.Errorf("Recognized() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import "fmt"
+
+// applyFilterAndGroups runs the shared post-fetch pipeline: the optional
+// filter/rename pass, then proxy-group and rule generation for whatever the
+// subscription(s) didn't already define. It mutates config in place and is
+// shared by the one-shot CLI path and the HTTP server mode.
+func applyFilterAndGroups(config *ClashConfig, filterPath, groupsConfigPath string) error {
+	if filterPath != "" {
+		filterCfg, err := loadFilterConfig(filterPath)
+		if err != nil {
+			return fmt.Errorf("loading filter config: %w", err)
+		}
+		config.Proxies, err = applyFilter(config.Proxies, filterCfg)
+		if err != nil {
+			return fmt.Errorf("applying filter: %w", err)
+		}
+	}
+
+	if len(config.ProxyGroups) == 0 || len(config.Rules) == 0 {
+		groupOpts := defaultGroupOptions()
+		if groupsConfigPath != "" {
+			loaded, err := loadGroupOptions(groupsConfigPath)
+			if err != nil {
+				return fmt.Errorf("loading groups config: %w", err)
+			}
+			groupOpts = *loaded
+		}
+		if len(config.ProxyGroups) == 0 {
+			config.ProxyGroups = generateProxyGroups(config, groupOpts)
+		}
+		if len(config.Rules) == 0 {
+			config.Rules = groupOpts.Rules
+		}
+	}
+
+	return nil
+}
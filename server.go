@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// serveSource is one named subscription configuration servable by -serve:
+// its own sources and optional filter/groups pipelines, so a single
+// instance can serve several devices differently.
+type serveSource struct {
+	Sources      []string `yaml:"sources"`
+	Filter       string   `yaml:"filter,omitempty"`
+	GroupsConfig string   `yaml:"groups-config,omitempty"`
+}
+
+// loadServeSourcesConfig reads a map of named serveSource entries from a
+// YAML file, keyed by the name clients pass as `?src=`.
+func loadServeSourcesConfig(path string) (map[string]serveSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading serve config %s: %w", path, err)
+	}
+
+	var named map[string]serveSource
+	if err := yaml.Unmarshal(data, &named); err != nil {
+		return nil, fmt.Errorf("parsing serve config %s: %w", path, err)
+	}
+	return named, nil
+}
+
+// cachedConfig is a generated config held in memory until it expires.
+type cachedConfig struct {
+	yamlData  []byte
+	userinfo  string
+	expiresAt time.Time
+}
+
+// configServer serves generated Clash configs over HTTP, caching each
+// named source's result for cacheTTL to avoid hammering upstream.
+type configServer struct {
+	defaultSource serveSource
+	named         map[string]serveSource
+	cacheTTL      time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedConfig
+}
+
+// runServer starts the HTTP server and blocks until it exits with an error.
+func runServer(addr, serveConfigPath string, defaultSource serveSource, cacheTTL time.Duration, tlsCert, tlsKey string) error {
+	named := map[string]serveSource{}
+	if serveConfigPath != "" {
+		loaded, err := loadServeSourcesConfig(serveConfigPath)
+		if err != nil {
+			return err
+		}
+		named = loaded
+	}
+
+	srv := &configServer{
+		defaultSource: defaultSource,
+		named:         named,
+		cacheTTL:      cacheTTL,
+		cache:         map[string]cachedConfig{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/clash.yaml", srv.handleDefault)
+	mux.HandleFunc("/clash", srv.handleNamed)
+
+	httpServer := &http.Server{Addr: addr, Handler: mux}
+
+	fmt.Fprintf(os.Stderr, "Serving Clash configs on %s\n", addr)
+	if tlsCert != "" || tlsKey != "" {
+		return httpServer.ListenAndServeTLS(tlsCert, tlsKey)
+	}
+	return httpServer.ListenAndServe()
+}
+
+func (s *configServer) handleDefault(w http.ResponseWriter, r *http.Request) {
+	s.serve(w, "", s.defaultSource)
+}
+
+func (s *configServer) handleNamed(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("src")
+	source := s.defaultSource
+	if name != "" {
+		named, ok := s.named[name]
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown source: %s", name), http.StatusNotFound)
+			return
+		}
+		source = named
+	}
+	s.serve(w, name, source)
+}
+
+// serve writes the cached config for cacheKey, regenerating it first if
+// missing or expired.
+func (s *configServer) serve(w http.ResponseWriter, cacheKey string, source serveSource) {
+	s.mu.Lock()
+	cached, ok := s.cache[cacheKey]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		writeConfigResponse(w, cached)
+		return
+	}
+
+	if len(source.Sources) == 0 {
+		http.Error(w, "no subscription sources configured", http.StatusInternalServerError)
+		return
+	}
+
+	config, userinfo, err := fetchSources(source.Sources)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if err := applyFilterAndGroups(config, source.Filter, source.GroupsConfig); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	defaultConfig, err := loadDefaultConfig()
+	if err != nil {
+		defaultConfig = &ClashConfig{}
+	}
+	merged := mergeConfigs(defaultConfig, config)
+
+	yamlData, err := yaml.Marshal(merged)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	entry := cachedConfig{yamlData: yamlData, userinfo: userinfo, expiresAt: time.Now().Add(s.cacheTTL)}
+	s.mu.Lock()
+	s.cache[cacheKey] = entry
+	s.mu.Unlock()
+
+	writeConfigResponse(w, entry)
+}
+
+func writeConfigResponse(w http.ResponseWriter, entry cachedConfig) {
+	w.Header().Set("Content-Type", "application/yaml")
+	if entry.userinfo != "" {
+		w.Header().Set("Subscription-Userinfo", entry.userinfo)
+	}
+	w.Write(entry.yamlData)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestUpstream(t *testing.T, yamlBody string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Subscription-Userinfo", "upload=1;download=2;total=3;expire=4")
+		w.Write([]byte(yamlBody))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestConfigServerHandleDefault(t *testing.T) {
+	upstream := newTestUpstream(t, "proxies:\n  - name: A\n    type: ss\n    server: a.example.com\n    port: 1\n")
+
+	srv := &configServer{
+		defaultSource: serveSource{Sources: []string{upstream.URL}},
+		named:         map[string]serveSource{},
+		cacheTTL:      time.Minute,
+		cache:         map[string]cachedConfig{},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/clash.yaml", nil)
+	rec := httptest.NewRecorder()
+	srv.handleDefault(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %v, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/yaml" {
+		t.Errorf("Content-Type = %v, want application/yaml", ct)
+	}
+	if ui := rec.Header().Get("Subscription-Userinfo"); ui != "upload=1;download=2;total=3;expire=4" {
+		t.Errorf("Subscription-Userinfo = %v, want upstream value echoed", ui)
+	}
+	if rec.Body.Len() == 0 {
+		t.Errorf("body is empty")
+	}
+}
+
+func TestConfigServerHandleNamedUnknown(t *testing.T) {
+	srv := &configServer{named: map[string]serveSource{}, cache: map[string]cachedConfig{}, cacheTTL: time.Minute}
+
+	req := httptest.NewRequest(http.MethodGet, "/clash?src=missing", nil)
+	rec := httptest.NewRecorder()
+	srv.handleNamed(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %v, want 404", rec.Code)
+	}
+}
+
+func TestConfigServerCachesResult(t *testing.T) {
+	requests := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("proxies:\n  - name: A\n    type: ss\n    server: a.example.com\n    port: 1\n"))
+	}))
+	t.Cleanup(upstream.Close)
+
+	srv := &configServer{
+		defaultSource: serveSource{Sources: []string{upstream.URL}},
+		named:         map[string]serveSource{},
+		cacheTTL:      time.Minute,
+		cache:         map[string]cachedConfig{},
+	}
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/clash.yaml", nil)
+		rec := httptest.NewRecorder()
+		srv.handleDefault(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %v, want 200", i, rec.Code)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("upstream requests = %v, want 1 (subsequent requests should hit cache)", requests)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/wallacegibbon/proxy-config-updater/clashconfig"
+	"github.com/wallacegibbon/proxy-config-updater/parsers"
+)
+
+// resolveSources builds the source list from the CLI inputs: -sources takes
+// priority as a comma-separated list of URL-files or inline URLs; otherwise
+// the legacy positional url-file is read as one source per non-empty line.
+func resolveSources(urlFile, sourcesFlag string) ([]string, error) {
+	var sources []string
+
+	if sourcesFlag != "" {
+		for _, s := range strings.Split(sourcesFlag, ",") {
+			s = strings.TrimSpace(s)
+			if s != "" {
+				sources = append(sources, s)
+			}
+		}
+	} else {
+		fileBytes, err := os.ReadFile(urlFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading URL file %s: %w", urlFile, err)
+		}
+		for _, line := range strings.Split(string(fileBytes), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" {
+				sources = append(sources, line)
+			}
+		}
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no subscription sources provided")
+	}
+	return sources, nil
+}
+
+// resolveSourceURL turns a source entry into a subscription URL: entries
+// starting with http:// or https:// are used as-is, anything else is
+// treated as a path to a file containing the URL (the original behavior).
+func resolveSourceURL(source string) (string, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return source, nil
+	}
+
+	fileBytes, err := os.ReadFile(source)
+	if err != nil {
+		return "", fmt.Errorf("reading URL file %s: %w", source, err)
+	}
+
+	url := strings.TrimSpace(string(fileBytes))
+	if url == "" {
+		return "", fmt.Errorf("URL file %s is empty", source)
+	}
+	return url, nil
+}
+
+// fetchAndParseSource fetches and decodes a single source, returning it as
+// a ClashConfig regardless of whether it was a URI list or Clash YAML,
+// along with the upstream HTTP response headers.
+func fetchAndParseSource(source string) (*ClashConfig, http.Header, error) {
+	subscriptionURL, err := resolveSourceURL(source)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encodedContent, headers, err := fetchContent(subscriptionURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching %s: %w", subscriptionURL, err)
+	}
+
+	decodedContent, err := decodeBase64(encodedContent)
+	if err != nil {
+		decodedContent = encodedContent
+	}
+
+	if parsers.Recognized(decodedContent) {
+		proxies, err := parseURIList(decodedContent)
+		if err != nil {
+			return nil, headers, fmt.Errorf("parsing URI list from %s: %w", subscriptionURL, err)
+		}
+		return &ClashConfig{Proxies: proxies}, headers, nil
+	}
+
+	config, err := parseClashConfig(decodedContent)
+	if err != nil {
+		return nil, headers, fmt.Errorf("parsing Clash config from %s: %w", subscriptionURL, err)
+	}
+	return config, headers, nil
+}
+
+// fetchSources fetches and parses all sources concurrently and combines
+// them into a single ClashConfig. Failed sources are skipped with a warning.
+// When exactly one source succeeds, its whole parsed config
+// (DNS/Tun/Sniffer/Hosts/Profile/scalars, proxy-groups and rules included)
+// is kept as-is; with more than one, the configs are
+// deep-merged via clashconfig.Merge so each source's DNS/Tun/etc. survive,
+// but proxy-groups/rules are left for the caller to regenerate since no
+// single source's groups apply to the merged proxy list. It also returns
+// the Subscription-Userinfo header of the first source that sent one, for
+// clients (like mihomo) that track traffic usage from it.
+func fetchSources(sources []string) (*ClashConfig, string, error) {
+	configs := make([]*ClashConfig, len(sources))
+	headers := make([]http.Header, len(sources))
+	errs := make([]error, len(sources))
+
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source string) {
+			defer wg.Done()
+			configs[i], headers[i], errs[i] = fetchAndParseSource(source)
+		}(i, source)
+	}
+	wg.Wait()
+
+	var ok []*ClashConfig
+	var userinfo string
+	for i, cfg := range configs {
+		if errs[i] != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping source %q: %v\n", sources[i], errs[i])
+			continue
+		}
+		ok = append(ok, cfg)
+		if userinfo == "" && headers[i] != nil {
+			userinfo = headers[i].Get("Subscription-Userinfo")
+		}
+	}
+
+	if len(ok) == 0 {
+		return nil, "", fmt.Errorf("all %d source(s) failed", len(sources))
+	}
+
+	if len(ok) == 1 {
+		merged := *ok[0]
+		return &merged, userinfo, nil
+	}
+
+	merged := &ClashConfig{}
+	for _, cfg := range ok {
+		merged = clashconfig.Merge(merged, cfg)
+	}
+	merged.ProxyGroups = nil
+	merged.Rules = nil
+
+	return merged, userinfo, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSourceURLInline(t *testing.T) {
+	url, err := resolveSourceURL("https://example.com/sub")
+	if err != nil {
+		t.Fatalf("resolveSourceURL() error = %v", err)
+	}
+	if url != "https://example.com/sub" {
+		t.Errorf("url = %v, want https://example.com/sub", url)
+	}
+}
+
+func TestResolveSourceURLFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "url.txt")
+	if err := os.WriteFile(path, []byte("https://example.com/sub\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	url, err := resolveSourceURL(path)
+	if err != nil {
+		t.Fatalf("resolveSourceURL() error = %v", err)
+	}
+	if url != "https://example.com/sub" {
+		t.Errorf("url = %v, want https://example.com/sub", url)
+	}
+}
+
+func TestResolveSources(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "urls.txt")
+	if err := os.WriteFile(path, []byte("https://a.example.com\n\nhttps://b.example.com\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	sources, err := resolveSources(path, "")
+	if err != nil {
+		t.Fatalf("resolveSources() error = %v", err)
+	}
+	if len(sources) != 2 {
+		t.Fatalf("len(sources) = %v, want 2", len(sources))
+	}
+
+	sources, err = resolveSources("", "https://c.example.com, https://d.example.com")
+	if err != nil {
+		t.Fatalf("resolveSources() error = %v", err)
+	}
+	if len(sources) != 2 || sources[0] != "https://c.example.com" || sources[1] != "https://d.example.com" {
+		t.Errorf("sources = %v, want [https://c.example.com https://d.example.com]", sources)
+	}
+}
+
+func TestResolveSourcesNoneProvided(t *testing.T) {
+	if _, err := resolveSources("", ""); err == nil {
+		t.Errorf("resolveSources() error = nil, want error when no sources given")
+	}
+}